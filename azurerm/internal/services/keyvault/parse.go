@@ -0,0 +1,51 @@
+package keyvault
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NestedItemId represents a Key Vault key/secret/certificate identifier, which is addressed by
+// the vault's base URL plus the item name and an optional version - omitting the version refers
+// to the current (auto-rotating) version of the item.
+type NestedItemId struct {
+	KeyVaultBaseUrl string
+	Name            string
+	Version         string
+}
+
+// ID returns the canonical Key Vault nested-item ID for this key, versioned if a Version is set.
+func (id NestedItemId) ID() string {
+	if id.Version == "" {
+		return fmt.Sprintf("%skeys/%s", id.KeyVaultBaseUrl, id.Name)
+	}
+
+	return fmt.Sprintf("%skeys/%s/%s", id.KeyVaultBaseUrl, id.Name, id.Version)
+}
+
+// ParseNestedItemID parses a Key Vault key identifier such as
+// "https://my-vault.vault.azure.net/keys/my-key/abcd1234" (versioned) or
+// "https://my-vault.vault.azure.net/keys/my-key" (versionless, auto-rotating) into its components.
+func ParseNestedItemID(id string) (*NestedItemId, error) {
+	parsed, err := url.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing Key Vault Key ID %q: %+v", id, err)
+	}
+
+	components := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(components) < 2 || components[0] != "keys" {
+		return nil, fmt.Errorf("Error parsing Key Vault Key ID %q: expected a path of the form `/keys/{name}` or `/keys/{name}/{version}`", id)
+	}
+
+	version := ""
+	if len(components) > 2 {
+		version = components[2]
+	}
+
+	return &NestedItemId{
+		KeyVaultBaseUrl: fmt.Sprintf("%s://%s/", parsed.Scheme, parsed.Host),
+		Name:            components[1],
+		Version:         version,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package keyvault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/mgmt/2018-02-14-preview/keyvault"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+// ValidateSoftDeleteAndPurgeProtection ensures the Key Vault identified by keyVaultId has both
+// soft delete and purge protection enabled, since the services backing CMK (Storage, ServiceBus, ...)
+// refuse - or can be left in an unrecoverable state - when either is missing. When the vault has
+// been soft-deleted and recoverSoftDeleted is set, the tombstone is purged so a fresh vault can be
+// recreated in its place; the caller is expected to re-run `terraform apply` once the purge completes.
+func ValidateSoftDeleteAndPurgeProtection(ctx context.Context, vaultClient *keyvault.VaultsClient, keyVaultId string, recoverSoftDeleted bool) error {
+	id, err := azure.ParseAzureResourceID(keyVaultId)
+	if err != nil {
+		return err
+	}
+
+	vaultName := id.Path["vaults"]
+	resourceGroup := id.ResourceGroup
+
+	resp, err := vaultClient.Get(ctx, resourceGroup, vaultName)
+	if err != nil {
+		if !utils.ResponseWasNotFound(resp.Response) {
+			return fmt.Errorf("Error retrieving Key Vault %q (Resource Group %q): %+v", vaultName, resourceGroup, err)
+		}
+
+		deletedVault, err := findSoftDeletedKeyVault(ctx, vaultClient, keyVaultId)
+		if err != nil {
+			return err
+		}
+		if deletedVault == nil {
+			return fmt.Errorf("Key Vault %q (Resource Group %q) was not found", vaultName, resourceGroup)
+		}
+
+		if !recoverSoftDeleted {
+			return fmt.Errorf("Key Vault %q (Resource Group %q) has been soft-deleted - either restore or purge it, or set `recover_soft_deleted_key_vault` to `true` to have it purged automatically", vaultName, resourceGroup)
+		}
+
+		if deletedVault.Properties == nil || deletedVault.Properties.Location == nil {
+			return fmt.Errorf("Unable to determine the location of soft-deleted Key Vault %q in order to purge it", vaultName)
+		}
+
+		if _, err := vaultClient.PurgeDeleted(ctx, vaultName, *deletedVault.Properties.Location); err != nil {
+			return fmt.Errorf("Error purging soft-deleted Key Vault %q: %+v", vaultName, err)
+		}
+
+		return fmt.Errorf("Key Vault %q (Resource Group %q) was soft-deleted and is now being purged - please re-run `terraform apply` once the purge has completed", vaultName, resourceGroup)
+	}
+
+	props := resp.Properties
+	if props == nil {
+		return fmt.Errorf("Could not determine the soft-delete/purge-protection configuration for Key Vault %q (Resource Group %q)", vaultName, resourceGroup)
+	}
+
+	if props.EnableSoftDelete == nil || !*props.EnableSoftDelete {
+		return fmt.Errorf("Key Vault %q (Resource Group %q) must have soft delete enabled to be used for a customer-managed key", vaultName, resourceGroup)
+	}
+
+	if props.EnablePurgeProtection == nil || !*props.EnablePurgeProtection {
+		return fmt.Errorf("Key Vault %q (Resource Group %q) must have purge protection enabled to be used for a customer-managed key", vaultName, resourceGroup)
+	}
+
+	return nil
+}
+
+// findSoftDeletedKeyVault looks for a soft-deleted Key Vault tombstone whose resource ID matches
+// the given Key Vault ID, since a soft-deleted vault is no longer reachable via Get.
+func findSoftDeletedKeyVault(ctx context.Context, vaultClient *keyvault.VaultsClient, keyVaultId string) (*keyvault.DeletedVault, error) {
+	iter, err := vaultClient.ListDeletedComplete(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing soft-deleted Key Vaults: %+v", err)
+	}
+
+	for iter.NotDone() {
+		deletedVault := iter.Value()
+		if props := deletedVault.Properties; props != nil && props.VaultID != nil && strings.EqualFold(*props.VaultID, keyVaultId) {
+			return &deletedVault, nil
+		}
+
+		if err := iter.NextWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("Error iterating soft-deleted Key Vaults: %+v", err)
+		}
+	}
+
+	return nil, nil
+}
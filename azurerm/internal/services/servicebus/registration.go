@@ -0,0 +1,31 @@
+package servicebus
+
+import "github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+type Registration struct{}
+
+// Name is the name of this Service
+func (r Registration) Name() string {
+	return "ServiceBus"
+}
+
+// WebsiteCategories returns the categories (in the website) this resource can be found in
+func (r Registration) WebsiteCategories() []string {
+	return []string{
+		"Messaging",
+	}
+}
+
+// SupportedDataSources returns the supported Data Sources supported by this Service
+func (r Registration) SupportedDataSources() map[string]*schema.Resource {
+	return map[string]*schema.Resource{}
+}
+
+// SupportedResources returns the supported Resources supported by this Service
+func (r Registration) SupportedResources() map[string]*schema.Resource {
+	return map[string]*schema.Resource{
+		"azurerm_servicebus_namespace":                      resourceArmServiceBusNamespace(),
+		"azurerm_servicebus_namespace_customer_managed_key": resourceArmServiceBusNamespaceCustomerManagedKey(),
+		"azurerm_servicebus_namespace_network_rule_set":     resourceArmServiceBusNamespaceNetworkRuleSet(),
+	}
+}
@@ -0,0 +1,296 @@
+package servicebus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/servicebus/mgmt/2018-01-01-preview/servicebus"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/tf"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/validate"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/tags"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/timeouts"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func possibleIdentityTypeValuesAsStrings() []string {
+	values := servicebus.PossibleIdentityTypeValues()
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, string(v))
+	}
+	return out
+}
+
+func resourceArmServiceBusNamespace() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmServiceBusNamespaceCreateUpdate,
+		Read:   resourceArmServiceBusNamespaceRead,
+		Update: resourceArmServiceBusNamespaceCreateUpdate,
+		Delete: resourceArmServiceBusNamespaceDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validate.NoEmptyStrings,
+			},
+
+			"resource_group_name": azure.SchemaResourceGroupName(),
+
+			"location": azure.SchemaLocation(),
+
+			"sku": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(servicebus.Basic),
+					string(servicebus.Standard),
+					string(servicebus.Premium),
+				}, false),
+			},
+
+			"capacity": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntInSlice([]int{1, 2, 4}),
+			},
+
+			"zone_redundant": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// SystemAssigned is the prerequisite for azurerm_servicebus_namespace_customer_managed_key
+			// and for granting this Namespace RBAC-based access to other resources.
+			"identity": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(possibleIdentityTypeValuesAsStrings(), false),
+						},
+
+						// Azure has been rolling out `UserAssigned` and `SystemAssigned, UserAssigned`
+						// identities across RPs, but the ServiceBus API doesn't support them yet. This
+						// is accepted ahead of time so configurations don't need an `identity` block
+						// shape change once it lands - for now it must be left empty.
+						"identity_ids": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: azure.ValidateResourceID,
+							},
+						},
+
+						"principal_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"tenant_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"tags": tags.Schema(),
+		},
+	}
+}
+
+func resourceArmServiceBusNamespaceCreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForCreate(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	name := d.Get("name").(string)
+	resourceGroup := d.Get("resource_group_name").(string)
+
+	if d.IsNewResource() {
+		existing, err := namespacesClient.Get(ctx, resourceGroup, name)
+		if err != nil {
+			if !utils.ResponseWasNotFound(existing.Response) {
+				return fmt.Errorf("Error checking for presence of existing ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+			}
+		}
+
+		if existing.ID != nil && *existing.ID != "" {
+			return tf.ImportAsExistsError("azurerm_servicebus_namespace", *existing.ID)
+		}
+	}
+
+	if identityIds, ok := d.GetOk("identity.0.identity_ids"); ok && len(identityIds.([]interface{})) > 0 {
+		return fmt.Errorf("`identity_ids` cannot be set - User Assigned Identities are not yet supported by the ServiceBus API")
+	}
+
+	location := azure.NormalizeLocation(d.Get("location").(string))
+	sku := d.Get("sku").(string)
+
+	parameters := servicebus.SBNamespace{
+		Location: utils.String(location),
+		Sku: &servicebus.SBSku{
+			Name: servicebus.SkuName(sku),
+			Tier: servicebus.SkuTier(sku),
+		},
+		Identity: expandServiceBusNamespaceIdentity(d.Get("identity").([]interface{})),
+		SBNamespaceProperties: &servicebus.SBNamespaceProperties{
+			ZoneRedundant: utils.Bool(d.Get("zone_redundant").(bool)),
+		},
+		Tags: tags.Expand(d.Get("tags").(map[string]interface{})),
+	}
+
+	if capacity, ok := d.GetOk("capacity"); ok {
+		parameters.Sku.Capacity = utils.Int32(int32(capacity.(int)))
+	}
+
+	future, err := namespacesClient.CreateOrUpdate(ctx, resourceGroup, name, parameters)
+	if err != nil {
+		return fmt.Errorf("Error creating/updating ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	if err := future.WaitForCompletionRef(ctx, namespacesClient.Client); err != nil {
+		return fmt.Errorf("Error waiting for creation/update of ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	resp, err := namespacesClient.Get(ctx, resourceGroup, name)
+	if err != nil {
+		return fmt.Errorf("Error retrieving ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	d.SetId(*resp.ID)
+
+	return resourceArmServiceBusNamespaceRead(d, meta)
+}
+
+func resourceArmServiceBusNamespaceRead(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	id, err := azure.ParseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	resourceGroup := id.ResourceGroup
+	name := id.Path["namespaces"]
+
+	resp, err := namespacesClient.Get(ctx, resourceGroup, name)
+	if err != nil {
+		if utils.ResponseWasNotFound(resp.Response) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	d.Set("name", name)
+	d.Set("resource_group_name", resourceGroup)
+
+	if location := resp.Location; location != nil {
+		d.Set("location", azure.NormalizeLocation(*location))
+	}
+
+	if sku := resp.Sku; sku != nil {
+		d.Set("sku", string(sku.Name))
+
+		if sku.Capacity != nil {
+			d.Set("capacity", int(*sku.Capacity))
+		}
+	}
+
+	if props := resp.SBNamespaceProperties; props != nil {
+		d.Set("zone_redundant", props.ZoneRedundant)
+	}
+
+	if err := d.Set("identity", flattenServiceBusNamespaceIdentity(resp.Identity)); err != nil {
+		return fmt.Errorf("Error flattening `identity`: %+v", err)
+	}
+
+	return tags.FlattenAndSet(d, resp.Tags)
+}
+
+func resourceArmServiceBusNamespaceDelete(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForDelete(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	id, err := azure.ParseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	resourceGroup := id.ResourceGroup
+	name := id.Path["namespaces"]
+
+	future, err := namespacesClient.Delete(ctx, resourceGroup, name)
+	if err != nil {
+		return fmt.Errorf("Error deleting ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	if err := future.WaitForCompletionRef(ctx, namespacesClient.Client); err != nil {
+		return fmt.Errorf("Error waiting for deletion of ServiceBus Namespace %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	return nil
+}
+
+func expandServiceBusNamespaceIdentity(input []interface{}) *servicebus.Identity {
+	if len(input) == 0 || input[0] == nil {
+		return nil
+	}
+
+	v := input[0].(map[string]interface{})
+	return &servicebus.Identity{
+		Type: servicebus.IdentityType(v["type"].(string)),
+	}
+}
+
+func flattenServiceBusNamespaceIdentity(input *servicebus.Identity) []interface{} {
+	if input == nil {
+		return make([]interface{}, 0)
+	}
+
+	principalId := ""
+	if input.PrincipalID != nil {
+		principalId = *input.PrincipalID
+	}
+
+	tenantId := ""
+	if input.TenantID != nil {
+		tenantId = *input.TenantID
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"type":         string(input.Type),
+			"identity_ids": make([]interface{}, 0),
+			"principal_id": principalId,
+			"tenant_id":    tenantId,
+		},
+	}
+}
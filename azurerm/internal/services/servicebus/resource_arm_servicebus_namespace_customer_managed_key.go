@@ -0,0 +1,227 @@
+package servicebus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/servicebus/mgmt/2018-01-01-preview/servicebus"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/validate"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/keyvault"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/timeouts"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func resourceArmServiceBusNamespaceCustomerManagedKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmServiceBusNamespaceCustomerManagedKeyCreateUpdate,
+		Read:   resourceArmServiceBusNamespaceCustomerManagedKeyRead,
+		Update: resourceArmServiceBusNamespaceCustomerManagedKeyCreateUpdate,
+		Delete: resourceArmServiceBusNamespaceCustomerManagedKeyDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"namespace_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: azure.ValidateResourceID,
+			},
+
+			// Accepts both versioned and versionless Key Vault Key IDs - a versionless ID lets the
+			// Namespace pick up new key versions automatically.
+			"key_vault_key_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validate.NoEmptyStrings,
+			},
+
+			// Premium Namespaces that fail over to a soft-deleted key vault would otherwise be stuck
+			// unable to reach their CMK, so purging is opt-in rather than automatic on every apply.
+			"recover_soft_deleted_key_vault": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"infrastructure_encryption_required": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+func resourceArmServiceBusNamespaceCustomerManagedKeyCreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	vaultClient := meta.(*clients.Client).KeyVault.VaultsClient
+	subscriptionId := meta.(*clients.Client).Account.SubscriptionId
+	ctx, cancel := timeouts.ForCreate(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := d.Get("namespace_id").(string)
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	namespace, err := namespacesClient.Get(ctx, resourceGroup, namespaceName)
+	if err != nil {
+		return fmt.Errorf("Error retrieving ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	if namespace.Sku == nil || namespace.Sku.Tier != servicebus.SkuTierPremium {
+		return fmt.Errorf("customer-managed keys can only be configured on Premium ServiceBus Namespaces, but %q is not Premium", namespaceName)
+	}
+
+	if namespace.Identity == nil || namespace.Identity.Type != servicebus.SystemAssigned {
+		return fmt.Errorf("ServiceBus Namespace %q must have a SystemAssigned Identity before a customer-managed key can be configured", namespaceName)
+	}
+
+	nestedItem, err := keyvault.ParseNestedItemID(d.Get("key_vault_key_id").(string))
+	if err != nil {
+		return err
+	}
+
+	keyVaultId, err := azure.GetKeyVaultIDFromBaseUrl(ctx, vaultClient, nestedItem.KeyVaultBaseUrl, subscriptionId)
+	if err != nil {
+		return fmt.Errorf("Error looking up Key Vault ID from base url %q: %+v", nestedItem.KeyVaultBaseUrl, err)
+	}
+	if keyVaultId == nil {
+		return fmt.Errorf("Unable to locate Key Vault at URL %q", nestedItem.KeyVaultBaseUrl)
+	}
+
+	recoverSoftDeleted := d.Get("recover_soft_deleted_key_vault").(bool)
+	if err := keyvault.ValidateSoftDeleteAndPurgeProtection(ctx, vaultClient, *keyVaultId, recoverSoftDeleted); err != nil {
+		return err
+	}
+
+	encryption := &servicebus.Encryption{
+		KeySource: servicebus.MicrosoftKeyVault,
+		KeyVaultProperties: &[]servicebus.KeyVaultProperties{
+			{
+				KeyName:     utils.String(nestedItem.Name),
+				KeyVaultURI: utils.String(nestedItem.KeyVaultBaseUrl),
+				KeyVersion:  utils.String(nestedItem.Version),
+			},
+		},
+	}
+
+	if v, ok := d.GetOkExists("infrastructure_encryption_required"); ok {
+		encryption.RequireInfrastructureEncryption = utils.Bool(v.(bool))
+	}
+
+	update := servicebus.SBNamespaceUpdateParameters{
+		SBNamespaceProperties: &servicebus.SBNamespaceProperties{
+			Encryption: encryption,
+		},
+	}
+
+	if _, err := namespacesClient.Update(ctx, resourceGroup, namespaceName, update); err != nil {
+		return fmt.Errorf("Error setting customer-managed key for ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/encryptionSettings", namespaceId))
+
+	return resourceArmServiceBusNamespaceCustomerManagedKeyRead(d, meta)
+}
+
+func resourceArmServiceBusNamespaceCustomerManagedKeyRead(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := strings.TrimSuffix(d.Id(), "/encryptionSettings")
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	namespace, err := namespacesClient.Get(ctx, resourceGroup, namespaceName)
+	if err != nil {
+		if utils.ResponseWasNotFound(namespace.Response) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	d.Set("namespace_id", namespaceId)
+
+	if props := namespace.SBNamespaceProperties; props != nil {
+		if encryption := props.Encryption; encryption != nil {
+			d.Set("infrastructure_encryption_required", encryption.RequireInfrastructureEncryption)
+
+			if keyVaultProperties := encryption.KeyVaultProperties; keyVaultProperties != nil && len(*keyVaultProperties) > 0 {
+				kv := (*keyVaultProperties)[0]
+				if kv.KeyVaultURI != nil && kv.KeyName != nil {
+					nestedItem := keyvault.NestedItemId{
+						KeyVaultBaseUrl: *kv.KeyVaultURI,
+						Name:            *kv.KeyName,
+					}
+					if kv.KeyVersion != nil {
+						nestedItem.Version = *kv.KeyVersion
+					}
+					d.Set("key_vault_key_id", nestedItem.ID())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceArmServiceBusNamespaceCustomerManagedKeyDelete(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForDelete(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := strings.TrimSuffix(d.Id(), "/encryptionSettings")
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	// there's no "platform-managed" KeySource constant exposed by the SDK (`PossibleKeySourceValues`
+	// only contains `Microsoft.KeyVault`) - clearing KeySource and KeyVaultProperties is the closest
+	// equivalent to reverting to Microsoft-managed keys. KeyVaultProperties is set to an explicit
+	// empty slice, rather than left nil, so it's not dropped from the request body as an unset field.
+	update := servicebus.SBNamespaceUpdateParameters{
+		SBNamespaceProperties: &servicebus.SBNamespaceProperties{
+			Encryption: &servicebus.Encryption{
+				KeySource:          "",
+				KeyVaultProperties: &[]servicebus.KeyVaultProperties{},
+			},
+		},
+	}
+
+	if _, err := namespacesClient.Update(ctx, resourceGroup, namespaceName, update); err != nil {
+		return fmt.Errorf("Error reverting ServiceBus Namespace %q (Resource Group %q) to platform-managed keys: %+v", namespaceName, resourceGroup, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package servicebus
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/servicebus/mgmt/2018-01-01-preview/servicebus"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/validate"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/timeouts"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func possibleNetworkRuleIPActionValuesAsStrings() []string {
+	values := servicebus.PossibleNetworkRuleIPActionValues()
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, string(v))
+	}
+	return out
+}
+
+func resourceArmServiceBusNamespaceNetworkRuleSet() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmServiceBusNamespaceNetworkRuleSetCreateUpdate,
+		Read:   resourceArmServiceBusNamespaceNetworkRuleSetRead,
+		Update: resourceArmServiceBusNamespaceNetworkRuleSetCreateUpdate,
+		Delete: resourceArmServiceBusNamespaceNetworkRuleSetDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"namespace_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: azure.ValidateResourceID,
+			},
+
+			"default_action": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  string(servicebus.Deny),
+				ValidateFunc: validation.StringInSlice([]string{
+					string(servicebus.Allow),
+					string(servicebus.Deny),
+				}, false),
+			},
+
+			"ip_rules": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_mask": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validate.NoEmptyStrings,
+						},
+						"action": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      string(servicebus.NetworkRuleIPActionAllow),
+							ValidateFunc: validation.StringInSlice(possibleNetworkRuleIPActionValuesAsStrings(), false),
+						},
+					},
+				},
+			},
+
+			"virtual_network_rule": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: azure.ValidateResourceID,
+						},
+						"ignore_missing_vnet_service_endpoint": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+
+		CustomizeDiff: func(d *schema.ResourceDiff, v interface{}) error {
+			defaultAction := d.Get("default_action").(string)
+			_, hasIPRules := d.GetOk("ip_rules")
+			_, hasVnetRules := d.GetOk("virtual_network_rule")
+
+			if defaultAction == string(servicebus.Allow) && !hasIPRules && !hasVnetRules {
+				log.Printf("[WARN] `default_action` is set to `Allow` with no `ip_rules` or `virtual_network_rule` blocks - this is equivalent to allowing public network access to this ServiceBus Namespace")
+			}
+
+			return nil
+		},
+	}
+}
+
+func resourceArmServiceBusNamespaceNetworkRuleSetCreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForCreate(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := d.Get("namespace_id").(string)
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	namespace, err := namespacesClient.Get(ctx, resourceGroup, namespaceName)
+	if err != nil {
+		return fmt.Errorf("Error retrieving ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	if namespace.Sku == nil || namespace.Sku.Tier != servicebus.SkuTierPremium {
+		return fmt.Errorf("network rule sets can only be configured on Premium ServiceBus Namespaces, but %q is not Premium", namespaceName)
+	}
+
+	parameters := servicebus.NetworkRuleSet{
+		NetworkRuleSetProperties: &servicebus.NetworkRuleSetProperties{
+			DefaultAction:       servicebus.DefaultAction(d.Get("default_action").(string)),
+			IPRules:             expandServiceBusNamespaceNetworkRuleSetIPRules(d.Get("ip_rules").([]interface{})),
+			VirtualNetworkRules: expandServiceBusNamespaceNetworkRuleSetVirtualNetworkRules(d.Get("virtual_network_rule").([]interface{})),
+		},
+	}
+
+	if _, err := namespacesClient.CreateOrUpdateNetworkRuleSet(ctx, resourceGroup, namespaceName, parameters); err != nil {
+		return fmt.Errorf("Error creating/updating Network Rule Set for ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/networkRuleSets/default", namespaceId))
+
+	return resourceArmServiceBusNamespaceNetworkRuleSetRead(d, meta)
+}
+
+func resourceArmServiceBusNamespaceNetworkRuleSetRead(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := strings.TrimSuffix(d.Id(), "/networkRuleSets/default")
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	resp, err := namespacesClient.GetNetworkRuleSet(ctx, resourceGroup, namespaceName)
+	if err != nil {
+		if utils.ResponseWasNotFound(resp.Response) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving Network Rule Set for ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	d.Set("namespace_id", namespaceId)
+
+	if props := resp.NetworkRuleSetProperties; props != nil {
+		d.Set("default_action", string(props.DefaultAction))
+
+		if err := d.Set("ip_rules", flattenServiceBusNamespaceNetworkRuleSetIPRules(props.IPRules)); err != nil {
+			return fmt.Errorf("Error flattening `ip_rules`: %+v", err)
+		}
+
+		if err := d.Set("virtual_network_rule", flattenServiceBusNamespaceNetworkRuleSetVirtualNetworkRules(props.VirtualNetworkRules)); err != nil {
+			return fmt.Errorf("Error flattening `virtual_network_rule`: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceArmServiceBusNamespaceNetworkRuleSetDelete(d *schema.ResourceData, meta interface{}) error {
+	namespacesClient := meta.(*clients.Client).ServiceBus.NamespacesClient
+	ctx, cancel := timeouts.ForDelete(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	namespaceId := strings.TrimSuffix(d.Id(), "/networkRuleSets/default")
+	id, err := azure.ParseAzureResourceID(namespaceId)
+	if err != nil {
+		return err
+	}
+
+	namespaceName := id.Path["namespaces"]
+	resourceGroup := id.ResourceGroup
+
+	// there's no "delete" operation for a Network Rule Set - resetting to the default
+	// (`Allow` with no rules) is the closest equivalent to removing this resource.
+	parameters := servicebus.NetworkRuleSet{
+		NetworkRuleSetProperties: &servicebus.NetworkRuleSetProperties{
+			DefaultAction: servicebus.Allow,
+		},
+	}
+
+	if _, err := namespacesClient.CreateOrUpdateNetworkRuleSet(ctx, resourceGroup, namespaceName, parameters); err != nil {
+		return fmt.Errorf("Error resetting Network Rule Set for ServiceBus Namespace %q (Resource Group %q): %+v", namespaceName, resourceGroup, err)
+	}
+
+	return nil
+}
+
+func expandServiceBusNamespaceNetworkRuleSetIPRules(input []interface{}) *[]servicebus.NWRuleSetIPRules {
+	result := make([]servicebus.NWRuleSetIPRules, 0)
+
+	for _, v := range input {
+		block := v.(map[string]interface{})
+		result = append(result, servicebus.NWRuleSetIPRules{
+			IPMask: utils.String(block["ip_mask"].(string)),
+			Action: servicebus.NetworkRuleIPAction(block["action"].(string)),
+		})
+	}
+
+	return &result
+}
+
+func flattenServiceBusNamespaceNetworkRuleSetIPRules(input *[]servicebus.NWRuleSetIPRules) []interface{} {
+	result := make([]interface{}, 0)
+	if input == nil {
+		return result
+	}
+
+	for _, v := range *input {
+		ipMask := ""
+		if v.IPMask != nil {
+			ipMask = *v.IPMask
+		}
+
+		result = append(result, map[string]interface{}{
+			"ip_mask": ipMask,
+			"action":  string(v.Action),
+		})
+	}
+
+	return result
+}
+
+func expandServiceBusNamespaceNetworkRuleSetVirtualNetworkRules(input []interface{}) *[]servicebus.NWRuleSetVirtualNetworkRules {
+	result := make([]servicebus.NWRuleSetVirtualNetworkRules, 0)
+
+	for _, v := range input {
+		block := v.(map[string]interface{})
+		result = append(result, servicebus.NWRuleSetVirtualNetworkRules{
+			Subnet: &servicebus.Subnet{
+				ID: utils.String(block["subnet_id"].(string)),
+			},
+			IgnoreMissingVnetServiceEndpoint: utils.Bool(block["ignore_missing_vnet_service_endpoint"].(bool)),
+		})
+	}
+
+	return &result
+}
+
+func flattenServiceBusNamespaceNetworkRuleSetVirtualNetworkRules(input *[]servicebus.NWRuleSetVirtualNetworkRules) []interface{} {
+	result := make([]interface{}, 0)
+	if input == nil {
+		return result
+	}
+
+	for _, v := range *input {
+		subnetId := ""
+		if v.Subnet != nil && v.Subnet.ID != nil {
+			subnetId = *v.Subnet.ID
+		}
+
+		ignoreMissingVnetServiceEndpoint := false
+		if v.IgnoreMissingVnetServiceEndpoint != nil {
+			ignoreMissingVnetServiceEndpoint = *v.IgnoreMissingVnetServiceEndpoint
+		}
+
+		result = append(result, map[string]interface{}{
+			"subnet_id":                             subnetId,
+			"ignore_missing_vnet_service_endpoint":  ignoreMissingVnetServiceEndpoint,
+		})
+	}
+
+	return result
+}
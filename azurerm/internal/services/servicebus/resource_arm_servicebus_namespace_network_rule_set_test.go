@@ -0,0 +1,191 @@
+package servicebus_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/acceptance"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func TestAccAzureRMServiceBusNamespaceNetworkRuleSet_basic(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_servicebus_namespace_network_rule_set", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMServiceBusNamespaceNetworkRuleSetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAzureRMServiceBusNamespaceNetworkRuleSet_basic(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMServiceBusNamespaceNetworkRuleSetExists(data.ResourceName),
+					resource.TestCheckResourceAttr(data.ResourceName, "default_action", "Deny"),
+					resource.TestCheckResourceAttr(data.ResourceName, "ip_rules.#", "1"),
+				),
+			},
+			data.ImportStep(),
+		},
+	})
+}
+
+func TestAccAzureRMServiceBusNamespaceNetworkRuleSet_update(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_servicebus_namespace_network_rule_set", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMServiceBusNamespaceNetworkRuleSetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAzureRMServiceBusNamespaceNetworkRuleSet_basic(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMServiceBusNamespaceNetworkRuleSetExists(data.ResourceName),
+					resource.TestCheckResourceAttr(data.ResourceName, "ip_rules.#", "1"),
+				),
+			},
+			{
+				Config: testAccAzureRMServiceBusNamespaceNetworkRuleSet_virtualNetworkRule(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMServiceBusNamespaceNetworkRuleSetExists(data.ResourceName),
+					resource.TestCheckResourceAttr(data.ResourceName, "ip_rules.#", "0"),
+					resource.TestCheckResourceAttr(data.ResourceName, "virtual_network_rule.#", "1"),
+				),
+			},
+			data.ImportStep(),
+		},
+	})
+}
+
+func testCheckAzureRMServiceBusNamespaceNetworkRuleSetExists(resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resourceName)
+		}
+
+		id, err := azure.ParseAzureResourceID(rs.Primary.Attributes["namespace_id"])
+		if err != nil {
+			return err
+		}
+
+		client := acceptance.AzureProvider.Meta().(*clients.Client).ServiceBus.NamespacesClient
+		ctx := acceptance.AzureProvider.Meta().(*clients.Client).StopContext
+
+		resp, err := client.GetNetworkRuleSet(ctx, id.ResourceGroup, id.Path["namespaces"])
+		if err != nil {
+			return fmt.Errorf("Bad: GetNetworkRuleSet on servicebus.NamespacesClient: %+v", err)
+		}
+
+		if utils.ResponseWasNotFound(resp.Response) {
+			return fmt.Errorf("Bad: Network Rule Set for ServiceBus Namespace %q (Resource Group %q) does not exist", id.Path["namespaces"], id.ResourceGroup)
+		}
+
+		return nil
+	}
+}
+
+func testCheckAzureRMServiceBusNamespaceNetworkRuleSetDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "azurerm_servicebus_namespace_network_rule_set" {
+			continue
+		}
+
+		id, err := azure.ParseAzureResourceID(rs.Primary.Attributes["namespace_id"])
+		if err != nil {
+			return err
+		}
+
+		client := acceptance.AzureProvider.Meta().(*clients.Client).ServiceBus.NamespacesClient
+		ctx := acceptance.AzureProvider.Meta().(*clients.Client).StopContext
+
+		resp, err := client.GetNetworkRuleSet(ctx, id.ResourceGroup, id.Path["namespaces"])
+		if err != nil {
+			if utils.ResponseWasNotFound(resp.Response) {
+				continue
+			}
+			return err
+		}
+
+		// the Namespace itself is torn down alongside this resource in these tests, so reaching
+		// this point (Namespace still present) isn't expected - surface
+		// a non-default DefaultAction as evidence the reset on Delete didn't happen.
+		if resp.NetworkRuleSetProperties != nil && resp.NetworkRuleSetProperties.DefaultAction != "Allow" {
+			return fmt.Errorf("Network Rule Set for ServiceBus Namespace %q (Resource Group %q) was not reset", id.Path["namespaces"], id.ResourceGroup)
+		}
+	}
+
+	return nil
+}
+
+func testAccAzureRMServiceBusNamespaceNetworkRuleSet_basic(data acceptance.TestData) string {
+	template := testAccAzureRMServiceBusNamespaceNetworkRuleSet_template(data)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_servicebus_namespace_network_rule_set" "test" {
+  namespace_id   = azurerm_servicebus_namespace.test.id
+  default_action = "Deny"
+
+  ip_rules {
+    ip_mask = "1.1.1.1"
+  }
+}
+`, template)
+}
+
+func testAccAzureRMServiceBusNamespaceNetworkRuleSet_virtualNetworkRule(data acceptance.TestData) string {
+	template := testAccAzureRMServiceBusNamespaceNetworkRuleSet_template(data)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_virtual_network" "test" {
+  name                = "acctestvnet-%d"
+  address_space       = ["10.0.0.0/16"]
+  location            = azurerm_resource_group.test.location
+  resource_group_name = azurerm_resource_group.test.name
+}
+
+resource "azurerm_subnet" "test" {
+  name                 = "acctestsubnet-%d"
+  resource_group_name  = azurerm_resource_group.test.name
+  virtual_network_name = azurerm_virtual_network.test.name
+  address_prefixes     = ["10.0.1.0/24"]
+  service_endpoints    = ["Microsoft.ServiceBus"]
+}
+
+resource "azurerm_servicebus_namespace_network_rule_set" "test" {
+  namespace_id   = azurerm_servicebus_namespace.test.id
+  default_action = "Deny"
+
+  virtual_network_rule {
+    subnet_id = azurerm_subnet.test.id
+  }
+}
+`, template, data.RandomInteger, data.RandomInteger)
+}
+
+func testAccAzureRMServiceBusNamespaceNetworkRuleSet_template(data acceptance.TestData) string {
+	return fmt.Sprintf(`
+provider "azurerm" {
+  features {}
+}
+
+resource "azurerm_resource_group" "test" {
+  name     = "acctestRG-%d"
+  location = "%s"
+}
+
+resource "azurerm_servicebus_namespace" "test" {
+  name                = "acctestservicebus%d"
+  location            = azurerm_resource_group.test.location
+  resource_group_name = azurerm_resource_group.test.name
+  sku                 = "Premium"
+  capacity            = 1
+}
+`, data.RandomInteger, data.Locations.Primary, data.RandomInteger)
+}
@@ -2,17 +2,26 @@ package storage
 
 import (
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-04-01/storage"
+	// EncryptionIdentity (used for `user_assigned_identity_id` below) isn't present on the
+	// 2019-04-01 Storage Management API - bumped to the first version that exposes it.
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-09-01/storage"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/validate"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/keyvault"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/timeouts"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
 )
 
+// storageAccountKeyVaultKeyVersionLatest is the sentinel accepted in place of a pinned
+// `key_version` to opt the Storage Account into Azure's automatic key rotation.
+const storageAccountKeyVaultKeyVersionLatest = "latest"
+
 func resourceArmStorageAccountEncryptionSettings() *schema.Resource {
 	return &schema.Resource{
 		Read:          resourceArmStorageAccountEncryptionSettingsRead,
@@ -38,6 +47,22 @@ func resourceArmStorageAccountEncryptionSettings() *schema.Resource {
 				ValidateFunc: azure.ValidateResourceID,
 			},
 
+			// Key Vault soft-delete is asynchronous - purging a soft-deleted vault can take several
+			// minutes to complete, so this is off by default and must be opted into explicitly.
+			"recover_soft_deleted_key_vault": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// Only required when the Storage Account is encrypted using a Key Vault key that's
+			// reached via a User Assigned Identity rather than the account's own access policy.
+			"user_assigned_identity_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: azure.ValidateResourceID,
+			},
+
 			"key_vault": {
 				Type:     schema.TypeList,
 				MaxItems: 1,
@@ -64,15 +89,20 @@ func resourceArmStorageAccountEncryptionSettings() *schema.Resource {
 							Required:     true,
 							ValidateFunc: validate.NoEmptyStrings,
 						},
+						// Leave blank (or set to "latest") to let the key auto-rotate; the
+						// in-effect version is then surfaced via `current_key_version`.
 						"key_version": {
-							Type:         schema.TypeString,
-							Required:     true,
-							ValidateFunc: validate.NoEmptyStrings,
+							Type:     schema.TypeString,
+							Optional: true,
 						},
 						"key_vault_uri": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"current_key_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -96,7 +126,12 @@ func resourceArmStorageAccountEncryptionSettingsCreateUpdate(d *schema.ResourceD
 	storageAccountName := id.Path["storageAccounts"]
 	resourceGroupName := id.ResourceGroup
 
-	// TODO: Validate that the key vault has both soft delete and purge protection enabled
+	if keyVaultId, ok := d.GetOk("key_vault.0.key_vault_id"); ok {
+		recoverSoftDeleted := d.Get("recover_soft_deleted_key_vault").(bool)
+		if err := keyvault.ValidateSoftDeleteAndPurgeProtection(ctx, vaultClient, keyVaultId.(string), recoverSoftDeleted); err != nil {
+			return err
+		}
+	}
 
 	// create the update object with the default values
 	opts := storage.AccountUpdateParameters{
@@ -128,6 +163,12 @@ func resourceArmStorageAccountEncryptionSettingsCreateUpdate(d *schema.ResourceD
 			keyVaultProperties.KeyVaultURI = utils.String(pKeyVaultBaseUrl)
 			opts.Encryption.KeyVaultProperties = keyVaultProperties
 			opts.Encryption.KeySource = storage.MicrosoftKeyvault
+
+			if userAssignedIdentityId, ok := d.GetOk("user_assigned_identity_id"); ok {
+				opts.Encryption.EncryptionIdentity = &storage.EncryptionIdentity{
+					EncryptionUserAssignedIdentity: utils.String(userAssignedIdentityId.(string)),
+				}
+			}
 		}
 	}
 
@@ -144,6 +185,7 @@ func resourceArmStorageAccountEncryptionSettingsCreateUpdate(d *schema.ResourceD
 
 func resourceArmStorageAccountEncryptionSettingsRead(d *schema.ResourceData, meta interface{}) error {
 	storageClient := meta.(*clients.Client).Storage.AccountsClient
+	keyVaultManagementClient := meta.(*clients.Client).KeyVault.ManagementClient
 	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
 	defer cancel()
 
@@ -179,8 +221,27 @@ func resourceArmStorageAccountEncryptionSettingsRead(d *schema.ResourceData, met
 			if keyVaultProperties := encryption.KeyVaultProperties; keyVaultProperties != nil {
 				keyVaultId := d.Get("key_vault.0.key_vault_id").(string)
 				keyVaultPolicyId := d.Get("key_vault.0.key_vault_policy_id").(string)
+				configuredKeyVersion := d.Get("key_vault.0.key_version").(string)
+
+				// best-effort: resolving the in-effect key version requires Key Vault data-plane
+				// `Get Key` permission, which existing configurations of this resource may not
+				// have. Don't fail the whole Read over it - just leave `current_key_version` blank.
+				currentKeyVersion := ""
+				if keyVaultProperties.KeyVaultURI != nil && keyVaultProperties.KeyName != nil {
+					keyBundle, err := keyVaultManagementClient.GetKey(ctx, *keyVaultProperties.KeyVaultURI, *keyVaultProperties.KeyName, "")
+					if err != nil {
+						log.Printf("[WARN] Error retrieving current version of key %q from Key Vault %q: %+v", *keyVaultProperties.KeyName, *keyVaultProperties.KeyVaultURI, err)
+					} else if keyBundle.Key != nil && keyBundle.Key.Kid != nil {
+						currentKeyVersion = lastPathSegment(*keyBundle.Key.Kid)
+					}
+				}
 
-				if err := d.Set("key_vault", flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties, keyVaultId, keyVaultPolicyId)); err != nil {
+				keyVault := flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties, keyVaultId, keyVaultPolicyId, configuredKeyVersion)
+				if len(keyVault) > 0 {
+					keyVault[0].(map[string]interface{})["current_key_version"] = currentKeyVersion
+				}
+
+				if err := d.Set("key_vault", keyVault); err != nil {
 					return fmt.Errorf("Error flattening `key_vault_properties`: %+v", err)
 				}
 			}
@@ -253,7 +314,7 @@ func resourceArmStorageAccountEncryptionSettingsImportState(d *schema.ResourceDa
 	if props := resp.AccountProperties; props != nil {
 		if encryption := props.Encryption; encryption != nil {
 			if keyVaultProperties := encryption.KeyVaultProperties; keyVaultProperties != nil {
-				if err := d.Set("key_vault", flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties, "", "")); err != nil {
+				if err := d.Set("key_vault", flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties, "", "", "")); err != nil {
 					return nil, fmt.Errorf("Error flattening `key_vault_properties` on import: %+v", err)
 				}
 			}
@@ -278,6 +339,10 @@ func expandAzureRmStorageAccountKeyVaultProperties(d *schema.ResourceData) *stor
 	v := vs[0].(map[string]interface{})
 	keyName := v["key_name"].(string)
 	keyVersion := v["key_version"].(string)
+	if keyVersion == storageAccountKeyVaultKeyVersionLatest {
+		// an empty `KeyVersion` is how the Storage RP opts the account into automatic key rotation
+		keyVersion = ""
+	}
 
 	return &storage.KeyVaultProperties{
 		KeyName:    utils.String(keyName),
@@ -285,7 +350,7 @@ func expandAzureRmStorageAccountKeyVaultProperties(d *schema.ResourceData) *stor
 	}
 }
 
-func flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties *storage.KeyVaultProperties, keyVaultId string, keyVaultPolicyId string) []interface{} {
+func flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties *storage.KeyVaultProperties, keyVaultId string, keyVaultPolicyId string, configuredKeyVersion string) []interface{} {
 	if keyVaultProperties == nil {
 		return make([]interface{}, 0)
 	}
@@ -302,12 +367,25 @@ func flattenAzureRmStorageAccountKeyVaultProperties(keyVaultProperties *storage.
 	if keyVaultProperties.KeyName != nil {
 		result["key_name"] = *keyVaultProperties.KeyName
 	}
-	if keyVaultProperties.KeyVersion != nil {
+
+	// a blank `KeyVersion` means the key auto-rotates - treat it as matching whatever sentinel
+	// ("" or "latest") the user configured, rather than drift.
+	if keyVaultProperties.KeyVersion != nil && *keyVaultProperties.KeyVersion != "" {
 		result["key_version"] = *keyVaultProperties.KeyVersion
+	} else {
+		result["key_version"] = configuredKeyVersion
 	}
+
 	if keyVaultProperties.KeyVaultURI != nil {
 		result["key_vault_uri"] = *keyVaultProperties.KeyVaultURI
 	}
 
 	return []interface{}{result}
 }
+
+// lastPathSegment extracts the version segment from a Key Vault key identifier
+// (e.g. "https://my-vault.vault.azure.net/keys/my-key/abcd1234" -> "abcd1234").
+func lastPathSegment(id string) string {
+	parts := strings.Split(strings.TrimRight(id, "/"), "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,393 @@
+package storage_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/acceptance"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func TestAccAzureRMStorageAccountEncryptionSettings_basic(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_storage_account_encryption_settings", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountEncryptionSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAzureRMStorageAccountEncryptionSettings_basic(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountEncryptionSettingsExists(data.ResourceName),
+				),
+			},
+			data.ImportStep(),
+		},
+	})
+}
+
+func TestAccAzureRMStorageAccountEncryptionSettings_requiresPurgeProtection(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_storage_account_encryption_settings", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountEncryptionSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccAzureRMStorageAccountEncryptionSettings_keyVaultWithoutPurgeProtection(data),
+				ExpectError: regexp.MustCompile("must have purge protection enabled"),
+			},
+		},
+	})
+}
+
+func TestAccAzureRMStorageAccountEncryptionSettings_keyVersionLatest(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_storage_account_encryption_settings", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountEncryptionSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				// `key_version` is left unset, opting the key into auto-rotation - this should
+				// round-trip through `current_key_version` being populated on Read
+				Config: testAccAzureRMStorageAccountEncryptionSettings_keyVersionLatest(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountEncryptionSettingsExists(data.ResourceName),
+					resource.TestCheckResourceAttr(data.ResourceName, "key_vault.0.key_version", ""),
+					resource.TestCheckResourceAttrSet(data.ResourceName, "key_vault.0.current_key_version"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzureRMStorageAccountEncryptionSettings_userAssignedIdentity(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_storage_account_encryption_settings", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountEncryptionSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAzureRMStorageAccountEncryptionSettings_userAssignedIdentity(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountEncryptionSettingsExists(data.ResourceName),
+					resource.TestCheckResourceAttrSet(data.ResourceName, "user_assigned_identity_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAzureRMStorageAccountEncryptionSettings_recoverSoftDeletedKeyVault(t *testing.T) {
+	data := acceptance.BuildTestData(t, "azurerm_storage_account_encryption_settings", "test")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { acceptance.PreCheck(t) },
+		Providers:    acceptance.SupportedProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountEncryptionSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				// create the vault and key, but not the encryption settings - the vault is then
+				// soft-deleted (and, further below, purged) out-of-band before the encryption
+				// settings resource ever gets applied against it
+				Config: testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault_setup(data),
+			},
+			{
+				// `key_vault_id` below is a literal ARM ID rather than `azurerm_key_vault.test.id` -
+				// if the vault were still a managed resource in this config, Terraform's own refresh
+				// would notice it's gone and `azurerm_key_vault`'s default soft-delete recovery would
+				// silently restore it before this resource's CreateUpdate ever ran, so the `has
+				// been soft-deleted` branch below would never actually be exercised
+				PreConfig:   func() { testDeleteAzureRMKeyVault(t, data, false) },
+				Config:      testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault(data, false),
+				ExpectError: regexp.MustCompile("has been soft-deleted"),
+			},
+			{
+				// same unmanaged-ID approach - `recover_soft_deleted_key_vault = true` purges the
+				// tombstone and (per `keyvault.ValidateSoftDeleteAndPurgeProtection`) returns an
+				// error asking the caller to re-apply, rather than succeeding in a single apply
+				Config:      testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault(data, true),
+				ExpectError: regexp.MustCompile("please re-run `terraform apply`"),
+			},
+			{
+				// the tombstone has now been purged above, freeing up the vault name - re-introduce
+				// `azurerm_key_vault.test` as a managed resource so it's recreated fresh, then apply
+				// the encryption settings resource against it to confirm the happy path afterwards
+				Config: testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault_recovered(data),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountEncryptionSettingsExists(data.ResourceName),
+				),
+			},
+		},
+	})
+}
+
+func testCheckAzureRMStorageAccountEncryptionSettingsExists(resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resourceName)
+		}
+
+		id, err := azure.ParseAzureResourceID(rs.Primary.Attributes["storage_account_id"])
+		if err != nil {
+			return err
+		}
+
+		client := acceptance.AzureProvider.Meta().(*clients.Client).Storage.AccountsClient
+		ctx := acceptance.AzureProvider.Meta().(*clients.Client).StopContext
+
+		resp, err := client.GetProperties(ctx, id.ResourceGroup, id.Path["storageAccounts"], "")
+		if err != nil {
+			return fmt.Errorf("Bad: Get on storageAccountsClient: %+v", err)
+		}
+
+		if utils.ResponseWasNotFound(resp.Response) {
+			return fmt.Errorf("Bad: Storage Account %q (Resource Group %q) does not exist", id.Path["storageAccounts"], id.ResourceGroup)
+		}
+
+		return nil
+	}
+}
+
+// testDeleteAzureRMKeyVault soft-deletes the test Key Vault out-of-band, so the following apply
+// can exercise the `has been soft-deleted` / `recover_soft_deleted_key_vault` code path. When
+// purge is true the tombstone is also purged immediately, simulating the purge having already
+// completed by the time the next apply runs.
+func testDeleteAzureRMKeyVault(t *testing.T, data acceptance.TestData, purge bool) {
+	client := acceptance.AzureProvider.Meta().(*clients.Client).KeyVault.VaultsClient
+	ctx := acceptance.AzureProvider.Meta().(*clients.Client).StopContext
+
+	resourceGroup := fmt.Sprintf("acctestRG-%d", data.RandomInteger)
+	vaultName := fmt.Sprintf("acctestkv%s", data.RandomString)
+
+	if _, err := client.Delete(ctx, resourceGroup, vaultName); err != nil {
+		t.Fatalf("Bad: deleting Key Vault %q (Resource Group %q): %+v", vaultName, resourceGroup, err)
+	}
+
+	if purge {
+		if _, err := client.PurgeDeleted(ctx, vaultName, data.Locations.Primary); err != nil {
+			t.Fatalf("Bad: purging Key Vault %q: %+v", vaultName, err)
+		}
+	}
+}
+
+func testCheckAzureRMStorageAccountEncryptionSettingsDestroy(s *terraform.State) error {
+	// this resource only ever modifies an existing Storage Account's encryption settings, so
+	// "destroy" resets them back to platform-managed keys rather than removing anything - there's
+	// nothing further to assert here.
+	return nil
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_basic(data acceptance.TestData) string {
+	template := testAccAzureRMStorageAccountEncryptionSettings_template(data, true)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id = azurerm_storage_account.test.id
+
+  key_vault {
+    key_vault_policy_id = azurerm_key_vault_access_policy.test.id
+    key_vault_id        = azurerm_key_vault.test.id
+    key_name            = azurerm_key_vault_key.test.name
+    key_version         = azurerm_key_vault_key.test.version
+  }
+}
+`, template)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_keyVaultWithoutPurgeProtection(data acceptance.TestData) string {
+	template := testAccAzureRMStorageAccountEncryptionSettings_template(data, false)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id = azurerm_storage_account.test.id
+
+  key_vault {
+    key_vault_policy_id = azurerm_key_vault_access_policy.test.id
+    key_vault_id        = azurerm_key_vault.test.id
+    key_name            = azurerm_key_vault_key.test.name
+    key_version         = azurerm_key_vault_key.test.version
+  }
+}
+`, template)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_keyVersionLatest(data acceptance.TestData) string {
+	template := testAccAzureRMStorageAccountEncryptionSettings_template(data, true)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id = azurerm_storage_account.test.id
+
+  key_vault {
+    key_vault_policy_id = azurerm_key_vault_access_policy.test.id
+    key_vault_id        = azurerm_key_vault.test.id
+    key_name            = azurerm_key_vault_key.test.name
+  }
+}
+`, template)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_userAssignedIdentity(data acceptance.TestData) string {
+	template := testAccAzureRMStorageAccountEncryptionSettings_template(data, true)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_user_assigned_identity" "test" {
+  name                = "acctestuai-%d"
+  resource_group_name = azurerm_resource_group.test.name
+  location            = azurerm_resource_group.test.location
+}
+
+resource "azurerm_key_vault_access_policy" "uai" {
+  key_vault_id = azurerm_key_vault.test.id
+  tenant_id    = azurerm_user_assigned_identity.test.tenant_id
+  object_id    = azurerm_user_assigned_identity.test.principal_id
+
+  key_permissions = ["get", "unwrapkey", "wrapkey"]
+}
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id        = azurerm_storage_account.test.id
+  user_assigned_identity_id = azurerm_user_assigned_identity.test.id
+
+  key_vault {
+    key_vault_policy_id = azurerm_key_vault_access_policy.test.id
+    key_vault_id        = azurerm_key_vault.test.id
+    key_name            = azurerm_key_vault_key.test.name
+    key_version         = azurerm_key_vault_key.test.version
+  }
+
+  depends_on = [azurerm_key_vault_access_policy.uai]
+}
+`, template, data.RandomInteger)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault_setup(data acceptance.TestData) string {
+	return testAccAzureRMStorageAccountEncryptionSettings_template(data, true)
+}
+
+// testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault deliberately does NOT
+// reference `azurerm_key_vault.test` - only the resource group stays managed. Once the vault has
+// been soft-deleted out-of-band, keeping it as a managed resource in this config would let
+// Terraform's own refresh notice it's gone and `azurerm_key_vault`'s default soft-delete recovery
+// would silently restore it before `azurerm_storage_account_encryption_settings` ever got a chance
+// to see the 404 itself. `key_vault_id`/`key_name` are instead built as literal values matching
+// what `_setup` above created, so only this resource's own Key Vault client calls touch the vault.
+func testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault(data acceptance.TestData, recover bool) string {
+	return fmt.Sprintf(`
+provider "azurerm" {
+  features {}
+}
+
+data "azurerm_client_config" "current" {}
+
+resource "azurerm_resource_group" "test" {
+  name     = "acctestRG-%d"
+  location = "%s"
+}
+
+resource "azurerm_storage_account" "test" {
+  name                     = "accsa%s"
+  resource_group_name      = azurerm_resource_group.test.name
+  location                 = azurerm_resource_group.test.location
+  account_tier             = "Standard"
+  account_replication_type = "LRS"
+}
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id             = azurerm_storage_account.test.id
+  recover_soft_deleted_key_vault = %t
+
+  key_vault {
+    key_vault_policy_id = azurerm_resource_group.test.id
+    key_vault_id        = "/subscriptions/${data.azurerm_client_config.current.subscription_id}/resourceGroups/${azurerm_resource_group.test.name}/providers/Microsoft.KeyVault/vaults/acctestkv%s"
+    key_name            = "acctestkvk-%d"
+  }
+}
+`, data.RandomInteger, data.Locations.Primary, data.RandomString, recover, data.RandomString, data.RandomInteger)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_softDeletedKeyVault_recovered(data acceptance.TestData) string {
+	template := testAccAzureRMStorageAccountEncryptionSettings_template(data, true)
+	return fmt.Sprintf(`
+%s
+
+resource "azurerm_storage_account_encryption_settings" "test" {
+  storage_account_id = azurerm_storage_account.test.id
+
+  key_vault {
+    key_vault_policy_id = azurerm_key_vault_access_policy.test.id
+    key_vault_id        = azurerm_key_vault.test.id
+    key_name            = azurerm_key_vault_key.test.name
+    key_version         = azurerm_key_vault_key.test.version
+  }
+}
+`, template)
+}
+
+func testAccAzureRMStorageAccountEncryptionSettings_template(data acceptance.TestData, purgeProtectionEnabled bool) string {
+	return fmt.Sprintf(`
+provider "azurerm" {
+  features {}
+}
+
+data "azurerm_client_config" "current" {}
+
+resource "azurerm_resource_group" "test" {
+  name     = "acctestRG-%d"
+  location = "%s"
+}
+
+resource "azurerm_key_vault" "test" {
+  name                     = "acctestkv%s"
+  location                 = azurerm_resource_group.test.location
+  resource_group_name      = azurerm_resource_group.test.name
+  tenant_id                = data.azurerm_client_config.current.tenant_id
+  sku_name                 = "standard"
+  soft_delete_enabled      = true
+  purge_protection_enabled = %t
+}
+
+resource "azurerm_key_vault_access_policy" "test" {
+  key_vault_id = azurerm_key_vault.test.id
+  tenant_id    = data.azurerm_client_config.current.tenant_id
+  object_id    = data.azurerm_client_config.current.object_id
+
+  key_permissions = ["create", "get", "delete", "purge"]
+}
+
+resource "azurerm_key_vault_key" "test" {
+  name         = "acctestkvk-%d"
+  key_vault_id = azurerm_key_vault.test.id
+  key_type     = "RSA"
+  key_size     = 2048
+  key_opts     = ["decrypt", "encrypt"]
+
+  depends_on = [azurerm_key_vault_access_policy.test]
+}
+
+resource "azurerm_storage_account" "test" {
+  name                     = "accsa%s"
+  resource_group_name      = azurerm_resource_group.test.name
+  location                 = azurerm_resource_group.test.location
+  account_tier             = "Standard"
+  account_replication_type = "LRS"
+}
+`, data.RandomInteger, data.Locations.Primary, data.RandomString, purgeProtectionEnabled, data.RandomInteger, data.RandomString)
+}